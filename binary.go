@@ -0,0 +1,67 @@
+package keccak
+
+import "errors"
+
+// magic identifies the encoding produced by MarshalBinary. It is
+// followed by the domain separation byte and the rate (in 64-bit lanes),
+// which together pin the encoding to a specific Keccak/SHA-3/SHAKE
+// variant so UnmarshalBinary can reject a mismatched state.
+var magic = [4]byte{'k', 'e', 'c', 'k'}
+
+var errInvalidState = errors.New("keccak: invalid hash state")
+var errMismatchedState = errors.New("keccak: mismatched hash state")
+
+// MarshalBinary implements encoding.BinaryMarshaler, allowing the
+// sponge state to be snapshotted mid-absorb and restored later, for
+// example across process restarts when hashing very large inputs.
+func (k *keccak) MarshalBinary() ([]byte, error) {
+	if k.squeezing {
+		return nil, errors.New("keccak: cannot marshal state while squeezing")
+	}
+
+	b := make([]byte, 0, len(magic)+2+25*8+1+len(k.buf))
+	b = append(b, magic[:]...)
+	b = append(b, k.domain, byte(k.blockSize/8))
+	for i := range k.S {
+		var w [8]byte
+		putUint64le(w[:], k.S[i])
+		b = append(b, w[:]...)
+	}
+	b = append(b, byte(len(k.buf)))
+	b = append(b, k.buf...)
+	return b, nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (k *keccak) UnmarshalBinary(data []byte) error {
+	if len(data) < len(magic)+2 || [4]byte{data[0], data[1], data[2], data[3]} != magic {
+		return errInvalidState
+	}
+	data = data[len(magic):]
+
+	domain, rate := data[0], data[1]
+	if domain != k.domain || rate != byte(k.blockSize/8) {
+		return errMismatchedState
+	}
+	data = data[2:]
+
+	if len(data) < 25*8+1 {
+		return errInvalidState
+	}
+	for i := range k.S {
+		k.S[i] = uint64le(data[i*8:])
+	}
+	data = data[25*8:]
+
+	n := int(data[0])
+	data = data[1:]
+	if len(data) < n {
+		return errInvalidState
+	}
+
+	k.buf = append([]byte(nil), data[:n]...)
+	k.squeezing = false
+	k.rateBuf = nil
+	k.rateOff = 0
+	return nil
+}
@@ -0,0 +1,43 @@
+package keccak
+
+import (
+	"bytes"
+	"encoding"
+	"testing"
+)
+
+func TestMarshalRoundTrip(t *testing.T) {
+	h1 := NewSHA3256()
+	h1.Write([]byte("hello "))
+	state, err := h1.(encoding.BinaryMarshaler).MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary: %v", err)
+	}
+	h1.Write([]byte("world"))
+	want := h1.Sum(nil)
+
+	h2 := NewSHA3256()
+	if err := h2.(encoding.BinaryUnmarshaler).UnmarshalBinary(state); err != nil {
+		t.Fatalf("UnmarshalBinary: %v", err)
+	}
+	h2.Write([]byte("world"))
+	got := h2.Sum(nil)
+
+	if !bytes.Equal(want, got) {
+		t.Fatalf("restored hash = %x, want %x", got, want)
+	}
+}
+
+func TestUnmarshalRejectsMismatchedVariant(t *testing.T) {
+	h1 := NewSHA3256()
+	h1.Write([]byte("hello"))
+	state, err := h1.(encoding.BinaryMarshaler).MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary: %v", err)
+	}
+
+	h2 := NewSHA3512()
+	if err := h2.(encoding.BinaryUnmarshaler).UnmarshalBinary(state); err == nil {
+		t.Fatalf("UnmarshalBinary accepted state from a different variant")
+	}
+}
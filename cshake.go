@@ -0,0 +1,352 @@
+package keccak
+
+import (
+	"hash"
+)
+
+// This file implements the NIST SP 800-185 functions built on top of
+// cSHAKE: cSHAKE itself, KMAC, TupleHash and ParallelHash.
+
+// leftEncode returns the NIST SP 800-185 left_encode of n: the minimal
+// big-endian encoding of n prefixed with its own length in bytes.
+func leftEncode(n uint64) []byte {
+	var buf [8]byte
+	for i := 0; i < 8; i++ {
+		buf[7-i] = byte(n >> uint(8*i))
+	}
+	i := 0
+	for i < 7 && buf[i] == 0 {
+		i++
+	}
+	enc := make([]byte, 0, 9)
+	enc = append(enc, byte(8-i))
+	enc = append(enc, buf[i:]...)
+	return enc
+}
+
+// rightEncode returns the NIST SP 800-185 right_encode of n: the
+// minimal big-endian encoding of n followed by its own length in
+// bytes.
+func rightEncode(n uint64) []byte {
+	var buf [8]byte
+	for i := 0; i < 8; i++ {
+		buf[7-i] = byte(n >> uint(8*i))
+	}
+	i := 0
+	for i < 7 && buf[i] == 0 {
+		i++
+	}
+	enc := make([]byte, 0, 9)
+	enc = append(enc, buf[i:]...)
+	enc = append(enc, byte(8-i))
+	return enc
+}
+
+// encodeString returns left_encode(len(s)*8) || s.
+func encodeString(s []byte) []byte {
+	return append(leftEncode(uint64(len(s))*8), s...)
+}
+
+// bytepad prepends left_encode(w) to x and right-pads the result with
+// zero bytes up to the next multiple of w.
+func bytepad(x []byte, w int) []byte {
+	buf := append(leftEncode(uint64(w)), x...)
+	if rem := len(buf) % w; rem != 0 {
+		buf = append(buf, make([]byte, w-rem)...)
+	}
+	return buf
+}
+
+// newCShake builds the cSHAKE sponge for the given capacity (in bits)
+// and function-name/customization strings, absorbing
+// bytepad(encode_string(N) || encode_string(S), rate) as its first
+// block. When N and S are both empty, cSHAKE is defined to be
+// identical to plain SHAKE, so the bytepad prefix is skipped and the
+// ordinary SHAKE domain separation byte is used instead of 0x04.
+func newCShake(capacity int, N, S []byte) *keccak {
+	domain := byte(domainCSHAKE)
+	if len(N) == 0 && len(S) == 0 {
+		domain = domainSHAKE
+	}
+
+	k := newKeccak(capacity, 0, domain).(*keccak)
+	if domain == domainCSHAKE {
+		prefix := append(encodeString(N), encodeString(S)...)
+		k.Write(bytepad(prefix, k.blockSize))
+	}
+	return k
+}
+
+// NewCShake128 creates a new cSHAKE128 ShakeHash with function-name
+// string N and customization string S.
+func NewCShake128(N, S []byte) ShakeHash {
+	return newCShake(128*2, N, S)
+}
+
+// NewCShake256 creates a new cSHAKE256 ShakeHash with function-name
+// string N and customization string S.
+func NewCShake256(N, S []byte) ShakeHash {
+	return newCShake(256*2, N, S)
+}
+
+// newKMAC builds the cSHAKE sponge for KMAC with function-name string
+// "KMAC" and customization string S, having already absorbed the
+// bytepad-ed key.
+func newKMAC(capacity int, key, S []byte) *keccak {
+	k := newCShake(capacity, []byte("KMAC"), S)
+	k.Write(bytepad(encodeString(key), k.blockSize))
+	return k
+}
+
+// kmac implements hash.Hash for a fixed KMAC output length. Sum
+// clones the absorbing sponge so that, like keccak.Sum, it can be
+// called repeatedly without disturbing the running hash.
+type kmac struct {
+	k        *keccak
+	capacity int
+	key, S   []byte
+	outBits  int
+}
+
+func (m *kmac) Write(p []byte) (int, error) { return m.k.Write(p) }
+
+// Reset rebuilds the underlying sponge from scratch, re-absorbing the
+// keyed cSHAKE prefix; zeroing m.k's state directly would leave the
+// key and customization string unabsorbed.
+func (m *kmac) Reset()         { m.k = newKMAC(m.capacity, m.key, m.S) }
+func (m *kmac) Size() int      { return m.outBits / 8 }
+func (m *kmac) BlockSize() int { return m.k.blockSize }
+
+func (m *kmac) Sum(b []byte) []byte {
+	c := m.k.Clone().(*keccak)
+	c.Write(rightEncode(uint64(m.outBits)))
+	out := make([]byte, m.outBits/8)
+	c.Read(out)
+	return append(b, out...)
+}
+
+// NewKMAC128 creates a new KMAC128 hash.Hash keyed by key, with
+// customization string S, producing outputLen bytes from Sum.
+func NewKMAC128(key, S []byte, outputLen int) hash.Hash {
+	return &kmac{
+		k:        newKMAC(128*2, key, S),
+		capacity: 128 * 2,
+		key:      append([]byte(nil), key...),
+		S:        append([]byte(nil), S...),
+		outBits:  outputLen * 8,
+	}
+}
+
+// NewKMAC256 creates a new KMAC256 hash.Hash keyed by key, with
+// customization string S, producing outputLen bytes from Sum.
+func NewKMAC256(key, S []byte, outputLen int) hash.Hash {
+	return &kmac{
+		k:        newKMAC(256*2, key, S),
+		capacity: 256 * 2,
+		key:      append([]byte(nil), key...),
+		S:        append([]byte(nil), S...),
+		outBits:  outputLen * 8,
+	}
+}
+
+// kmacXOF implements ShakeHash for KMAC's arbitrary-length XOF mode
+// (KMACXOF), which appends right_encode(0) rather than right_encode(L)
+// before squeezing.
+type kmacXOF struct {
+	k        *keccak
+	capacity int
+	key, S   []byte
+	started  bool
+}
+
+func (m *kmacXOF) Write(p []byte) (int, error) { return m.k.Write(p) }
+
+// Reset rebuilds the underlying sponge from scratch; see kmac.Reset.
+func (m *kmacXOF) Reset() {
+	m.k = newKMAC(m.capacity, m.key, m.S)
+	m.started = false
+}
+
+func (m *kmacXOF) Read(p []byte) (int, error) {
+	if !m.started {
+		m.started = true
+		m.k.Write(rightEncode(0))
+	}
+	return m.k.Read(p)
+}
+
+func (m *kmacXOF) Clone() ShakeHash {
+	return &kmacXOF{
+		k:        m.k.Clone().(*keccak),
+		capacity: m.capacity,
+		key:      m.key,
+		S:        m.S,
+		started:  m.started,
+	}
+}
+
+// NewKMACXOF128 creates a new KMAC128 ShakeHash keyed by key, with
+// customization string S, for streaming arbitrary-length output.
+func NewKMACXOF128(key, S []byte) ShakeHash {
+	return &kmacXOF{
+		k:        newKMAC(128*2, key, S),
+		capacity: 128 * 2,
+		key:      append([]byte(nil), key...),
+		S:        append([]byte(nil), S...),
+	}
+}
+
+// NewKMACXOF256 creates a new KMAC256 ShakeHash keyed by key, with
+// customization string S, for streaming arbitrary-length output.
+func NewKMACXOF256(key, S []byte) ShakeHash {
+	return &kmacXOF{
+		k:        newKMAC(256*2, key, S),
+		capacity: 256 * 2,
+		key:      append([]byte(nil), key...),
+		S:        append([]byte(nil), S...),
+	}
+}
+
+// tupleHash implements hash.Hash for TupleHash. Unlike an ordinary
+// hash.Hash, each call to Write absorbs one element of the tuple
+// rather than a raw stream of bytes: the caller calls Write once per
+// input element.
+type tupleHash struct {
+	k        *keccak
+	capacity int
+	S        []byte
+	outBits  int
+}
+
+func newTupleHash(capacity int, S []byte, outputLen int) *tupleHash {
+	return &tupleHash{
+		k:        newCShake(capacity, []byte("TupleHash"), S),
+		capacity: capacity,
+		S:        append([]byte(nil), S...),
+		outBits:  outputLen * 8,
+	}
+}
+
+func (t *tupleHash) Write(element []byte) (int, error) {
+	t.k.Write(encodeString(element))
+	return len(element), nil
+}
+
+// Reset rebuilds the underlying sponge from scratch, re-absorbing the
+// cSHAKE("TupleHash", S) prefix; see kmac.Reset.
+func (t *tupleHash) Reset() {
+	t.k = newCShake(t.capacity, []byte("TupleHash"), t.S)
+}
+func (t *tupleHash) Size() int      { return t.outBits / 8 }
+func (t *tupleHash) BlockSize() int { return t.k.blockSize }
+
+func (t *tupleHash) Sum(b []byte) []byte {
+	c := t.k.Clone().(*keccak)
+	c.Write(rightEncode(uint64(t.outBits)))
+	out := make([]byte, t.outBits/8)
+	c.Read(out)
+	return append(b, out...)
+}
+
+// NewTupleHash128 creates a new TupleHash128 hash.Hash with
+// customization string S, producing outputLen bytes from Sum. Each
+// Write call absorbs one element of the tuple.
+func NewTupleHash128(S []byte, outputLen int) hash.Hash {
+	return newTupleHash(128*2, S, outputLen)
+}
+
+// NewTupleHash256 creates a new TupleHash256 hash.Hash with
+// customization string S, producing outputLen bytes from Sum. Each
+// Write call absorbs one element of the tuple.
+func NewTupleHash256(S []byte, outputLen int) hash.Hash {
+	return newTupleHash(256*2, S, outputLen)
+}
+
+// parallelHash implements hash.Hash for ParallelHash. Input is
+// buffered as it is written and split into blockSize-byte leaves at
+// Sum time; each leaf is hashed independently with cSHAKE (N = S =
+// "") to an output of 2*securityStrength bits, and the concatenated
+// leaf digests are absorbed by the outer cSHAKE.
+type parallelHash struct {
+	k         *keccak
+	capacity  int
+	blockSize int
+	S         []byte
+	outBits   int
+	buf       []byte
+}
+
+func newParallelHash(capacity, blockSize int, S []byte, outputLen int) *parallelHash {
+	if blockSize <= 0 {
+		panic("keccak: ParallelHash block size must be positive")
+	}
+	return &parallelHash{
+		k:         newCShake(capacity, []byte("ParallelHash"), S),
+		capacity:  capacity,
+		blockSize: blockSize,
+		S:         append([]byte(nil), S...),
+		outBits:   outputLen * 8,
+	}
+}
+
+func (p *parallelHash) Write(b []byte) (int, error) {
+	p.buf = append(p.buf, b...)
+	return len(b), nil
+}
+
+// Reset rebuilds the underlying sponge from scratch, re-absorbing the
+// cSHAKE("ParallelHash", S) prefix; see kmac.Reset.
+func (p *parallelHash) Reset() {
+	p.k = newCShake(p.capacity, []byte("ParallelHash"), p.S)
+	p.buf = nil
+}
+func (p *parallelHash) Size() int      { return p.outBits / 8 }
+func (p *parallelHash) BlockSize() int { return p.k.blockSize }
+
+func (p *parallelHash) Sum(b []byte) []byte {
+	leafOutBytes := p.capacity / 8 // leaf output is 2*lambda bits == capacity bits
+	var leaves []byte
+	nBlocks := uint64(0)
+	data := p.buf
+	if len(data) == 0 {
+		data = []byte{}
+	}
+	for {
+		n := p.blockSize
+		if n > len(data) {
+			n = len(data)
+		}
+		leaf := newCShake(p.capacity, nil, nil)
+		leaf.Write(data[:n])
+		out := make([]byte, leafOutBytes)
+		leaf.Read(out)
+		leaves = append(leaves, out...)
+		nBlocks++
+		data = data[n:]
+		if len(data) == 0 {
+			break
+		}
+	}
+
+	c := p.k.Clone().(*keccak)
+	c.Write(leaves)
+	c.Write(rightEncode(nBlocks))
+	c.Write(rightEncode(uint64(p.outBits)))
+	out := make([]byte, p.outBits/8)
+	c.Read(out)
+	return append(b, out...)
+}
+
+// NewParallelHash128 creates a new ParallelHash128 hash.Hash that
+// splits its input into blockSize-byte leaves, with customization
+// string S, producing outputLen bytes from Sum.
+func NewParallelHash128(blockSize int, S []byte, outputLen int) hash.Hash {
+	return newParallelHash(128*2, blockSize, S, outputLen)
+}
+
+// NewParallelHash256 creates a new ParallelHash256 hash.Hash that
+// splits its input into blockSize-byte leaves, with customization
+// string S, producing outputLen bytes from Sum.
+func NewParallelHash256(blockSize int, S []byte, outputLen int) hash.Hash {
+	return newParallelHash(256*2, blockSize, S, outputLen)
+}
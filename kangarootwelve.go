@@ -0,0 +1,261 @@
+package keccak
+
+const k12ChunkSize = 8192
+const k12Rate = 168 // rate for a 256-bit capacity, same as SHAKE128
+
+// Domain separation bytes for the final node. The single-chunk value
+// is the same byte as the fixed-output SHA-3 domain (0x06).
+// k12DomainLeaf differs from both final-node values so a leaf
+// chaining value can never be mistaken for either finalization.
+const (
+	k12DomainSingleChunk = 0x06
+	k12DomainMultiChunk  = 0x0B
+	k12DomainLeaf        = 0x0C
+)
+
+// kangarooTwelve implements the K12 tree hash: the message is split
+// into 8192-byte chunks, each non-first chunk is reduced to a 256-bit
+// chaining value with 12-round Keccak-p[1600,12], and the first chunk
+// plus the concatenated chaining values are absorbed by a final node
+// that is itself a Keccak-p[1600,12] sponge exposed as a ShakeHash.
+type kangarooTwelve struct {
+	customization []byte
+	buf           []byte
+	final         *keccak
+}
+
+// NewKangarooTwelve creates a K12 tree hash with the given
+// customization string, exposed as a ShakeHash so callers can stream
+// arbitrary amounts of output.
+func NewKangarooTwelve(customization []byte) ShakeHash {
+	return &kangarooTwelve{customization: append([]byte(nil), customization...)}
+}
+
+func (k *kangarooTwelve) Write(p []byte) (int, error) {
+	if k.final != nil {
+		return 0, errWriteAfterRead
+	}
+	k.buf = append(k.buf, p...)
+	return len(p), nil
+}
+
+func (k *kangarooTwelve) Read(p []byte) (int, error) {
+	if k.final == nil {
+		k.final = k.buildFinal()
+	}
+	return k.final.Read(p)
+}
+
+func (k *kangarooTwelve) Clone() ShakeHash {
+	k2 := &kangarooTwelve{
+		customization: append([]byte(nil), k.customization...),
+		buf:           append([]byte(nil), k.buf...),
+	}
+	if k.final != nil {
+		k2.final = k.final.Clone().(*keccak)
+	}
+	return k2
+}
+
+func (k *kangarooTwelve) Reset() {
+	k.buf = nil
+	k.final = nil
+}
+
+// buildFinal assembles the final node, absorbing either the whole
+// message (single chunk) or the first chunk followed by the
+// concatenated chaining values of the remaining chunks (multi chunk).
+// The final node is a Keccak-p[1600,12] sponge, like every other
+// permutation call in K12, not the full 24-round keccakf.
+func (k *kangarooTwelve) buildFinal() *keccak {
+	msg := append(append([]byte(nil), k.buf...), k.customization...)
+	msg = append(msg, rightEncode(uint64(len(k.customization)))...)
+
+	if len(msg) <= k12ChunkSize {
+		node := newKeccak(128*2, 0, k12DomainSingleChunk).(*keccak)
+		node.permute = keccakf12
+		node.Write(msg)
+		return node
+	}
+
+	node := newKeccak(128*2, 0, k12DomainMultiChunk).(*keccak)
+	node.permute = keccakf12
+
+	first, rest := msg[:k12ChunkSize], msg[k12ChunkSize:]
+	node.Write(first)
+	node.Write([]byte{0x03, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00})
+
+	var numChainingValues uint64
+	for len(rest) > 0 {
+		n := k12ChunkSize
+		if n > len(rest) {
+			n = len(rest)
+		}
+		node.Write(k12LeafHash(rest[:n]))
+		rest = rest[n:]
+		numChainingValues++
+	}
+
+	// The NodeStar trailer binds the number of chaining values into
+	// the final absorb, so two messages whose chunks happen to hash
+	// to the same chaining-value stream can't collide.
+	node.Write(rightEncode(numChainingValues))
+	node.Write([]byte{0xFF, 0xFF})
+
+	return node
+}
+
+// k12LeafHash reduces chunk to a 32-byte chaining value using
+// 12-round Keccak-p[1600,12] instead of the full 24-round
+// permutation.
+func k12LeafHash(chunk []byte) []byte {
+	var S [25]uint64
+
+	for len(chunk) >= k12Rate {
+		xorIn(&S, chunk[:k12Rate])
+		keccakf12(&S)
+		chunk = chunk[k12Rate:]
+	}
+
+	block := make([]byte, k12Rate)
+	copy(block, chunk)
+	block[len(chunk)] = k12DomainLeaf
+	block[k12Rate-1] |= 0x80
+	xorIn(&S, block)
+	keccakf12(&S)
+
+	out := make([]byte, 32)
+	for i := 0; i < 4; i++ {
+		putUint64le(out[i*8:], S[i])
+	}
+	return out
+}
+
+// keccakf12 is Keccak-p[1600,12]: the same permutation as keccakf but
+// with only the last 12 of the 24 rounds applied, as used for K12's
+// leaf nodes.
+func keccakf12(S *[25]uint64) {
+	var bc [5]uint64
+	var tmp uint64
+
+	for r := rounds - 12; r < rounds; r++ {
+		// theta
+		bc[0] = S[0] ^ S[5] ^ S[10] ^ S[15] ^ S[20]
+		bc[1] = S[1] ^ S[6] ^ S[11] ^ S[16] ^ S[21]
+		bc[2] = S[2] ^ S[7] ^ S[12] ^ S[17] ^ S[22]
+		bc[3] = S[3] ^ S[8] ^ S[13] ^ S[18] ^ S[23]
+		bc[4] = S[4] ^ S[9] ^ S[14] ^ S[19] ^ S[24]
+		tmp = bc[4] ^ (bc[1]<<1 | bc[1]>>(64-1))
+		S[0] ^= tmp
+		S[5] ^= tmp
+		S[10] ^= tmp
+		S[15] ^= tmp
+		S[20] ^= tmp
+		tmp = bc[0] ^ (bc[2]<<1 | bc[2]>>(64-1))
+		S[1] ^= tmp
+		S[6] ^= tmp
+		S[11] ^= tmp
+		S[16] ^= tmp
+		S[21] ^= tmp
+		tmp = bc[1] ^ (bc[3]<<1 | bc[3]>>(64-1))
+		S[2] ^= tmp
+		S[7] ^= tmp
+		S[12] ^= tmp
+		S[17] ^= tmp
+		S[22] ^= tmp
+		tmp = bc[2] ^ (bc[4]<<1 | bc[4]>>(64-1))
+		S[3] ^= tmp
+		S[8] ^= tmp
+		S[13] ^= tmp
+		S[18] ^= tmp
+		S[23] ^= tmp
+		tmp = bc[3] ^ (bc[0]<<1 | bc[0]>>(64-1))
+		S[4] ^= tmp
+		S[9] ^= tmp
+		S[14] ^= tmp
+		S[19] ^= tmp
+		S[24] ^= tmp
+
+		// rho phi
+		tmp = S[1]
+		tmp, S[10] = S[10], tmp<<1|tmp>>(64-1)
+		tmp, S[7] = S[7], tmp<<3|tmp>>(64-3)
+		tmp, S[11] = S[11], tmp<<6|tmp>>(64-6)
+		tmp, S[17] = S[17], tmp<<10|tmp>>(64-10)
+		tmp, S[18] = S[18], tmp<<15|tmp>>(64-15)
+		tmp, S[3] = S[3], tmp<<21|tmp>>(64-21)
+		tmp, S[5] = S[5], tmp<<28|tmp>>(64-28)
+		tmp, S[16] = S[16], tmp<<36|tmp>>(64-36)
+		tmp, S[8] = S[8], tmp<<45|tmp>>(64-45)
+		tmp, S[21] = S[21], tmp<<55|tmp>>(64-55)
+		tmp, S[24] = S[24], tmp<<2|tmp>>(64-2)
+		tmp, S[4] = S[4], tmp<<14|tmp>>(64-14)
+		tmp, S[15] = S[15], tmp<<27|tmp>>(64-27)
+		tmp, S[23] = S[23], tmp<<41|tmp>>(64-41)
+		tmp, S[19] = S[19], tmp<<56|tmp>>(64-56)
+		tmp, S[13] = S[13], tmp<<8|tmp>>(64-8)
+		tmp, S[12] = S[12], tmp<<25|tmp>>(64-25)
+		tmp, S[2] = S[2], tmp<<43|tmp>>(64-43)
+		tmp, S[20] = S[20], tmp<<62|tmp>>(64-62)
+		tmp, S[14] = S[14], tmp<<18|tmp>>(64-18)
+		tmp, S[22] = S[22], tmp<<39|tmp>>(64-39)
+		tmp, S[9] = S[9], tmp<<61|tmp>>(64-61)
+		tmp, S[6] = S[6], tmp<<20|tmp>>(64-20)
+		S[1] = tmp<<44 | tmp>>(64-44)
+
+		// chi
+		bc[0] = S[0]
+		bc[1] = S[1]
+		bc[2] = S[2]
+		bc[3] = S[3]
+		bc[4] = S[4]
+		S[0] ^= (^bc[1]) & bc[2]
+		S[1] ^= (^bc[2]) & bc[3]
+		S[2] ^= (^bc[3]) & bc[4]
+		S[3] ^= (^bc[4]) & bc[0]
+		S[4] ^= (^bc[0]) & bc[1]
+		bc[0] = S[5]
+		bc[1] = S[6]
+		bc[2] = S[7]
+		bc[3] = S[8]
+		bc[4] = S[9]
+		S[5] ^= (^bc[1]) & bc[2]
+		S[6] ^= (^bc[2]) & bc[3]
+		S[7] ^= (^bc[3]) & bc[4]
+		S[8] ^= (^bc[4]) & bc[0]
+		S[9] ^= (^bc[0]) & bc[1]
+		bc[0] = S[10]
+		bc[1] = S[11]
+		bc[2] = S[12]
+		bc[3] = S[13]
+		bc[4] = S[14]
+		S[10] ^= (^bc[1]) & bc[2]
+		S[11] ^= (^bc[2]) & bc[3]
+		S[12] ^= (^bc[3]) & bc[4]
+		S[13] ^= (^bc[4]) & bc[0]
+		S[14] ^= (^bc[0]) & bc[1]
+		bc[0] = S[15]
+		bc[1] = S[16]
+		bc[2] = S[17]
+		bc[3] = S[18]
+		bc[4] = S[19]
+		S[15] ^= (^bc[1]) & bc[2]
+		S[16] ^= (^bc[2]) & bc[3]
+		S[17] ^= (^bc[3]) & bc[4]
+		S[18] ^= (^bc[4]) & bc[0]
+		S[19] ^= (^bc[0]) & bc[1]
+		bc[0] = S[20]
+		bc[1] = S[21]
+		bc[2] = S[22]
+		bc[3] = S[23]
+		bc[4] = S[24]
+		S[20] ^= (^bc[1]) & bc[2]
+		S[21] ^= (^bc[2]) & bc[3]
+		S[22] ^= (^bc[3]) & bc[4]
+		S[23] ^= (^bc[4]) & bc[0]
+		S[24] ^= (^bc[0]) & bc[1]
+
+		// iota
+		S[0] ^= roundConstants[r]
+	}
+}
@@ -0,0 +1,77 @@
+package keccak
+
+import (
+	"bytes"
+	"testing"
+)
+
+func readAllK12(t *testing.T, h ShakeHash, n int) []byte {
+	t.Helper()
+	out := make([]byte, n)
+	if _, err := h.Read(out); err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	return out
+}
+
+func TestKangarooTwelveDeterministic(t *testing.T) {
+	for _, msg := range [][]byte{
+		[]byte("hello"),
+		bytes.Repeat([]byte("x"), k12ChunkSize),      // one chunk of message; the customization encoding makes it two
+		bytes.Repeat([]byte("x"), k12ChunkSize+1),    // just over one chunk of message
+		bytes.Repeat([]byte("x"), 3*k12ChunkSize+17), // several chunks
+	} {
+		h1 := NewKangarooTwelve(nil)
+		h1.Write(msg)
+		out1 := readAllK12(t, h1, 32)
+
+		h2 := NewKangarooTwelve(nil)
+		h2.Write(msg)
+		out2 := readAllK12(t, h2, 32)
+
+		if !bytes.Equal(out1, out2) {
+			t.Fatalf("KangarooTwelve(%d bytes) not deterministic", len(msg))
+		}
+	}
+}
+
+func TestKangarooTwelveDistinguishesInputs(t *testing.T) {
+	short := NewKangarooTwelve(nil)
+	short.Write([]byte("short"))
+	outShort := readAllK12(t, short, 32)
+
+	long := NewKangarooTwelve(nil)
+	long.Write(bytes.Repeat([]byte("x"), 3*k12ChunkSize))
+	outLong := readAllK12(t, long, 32)
+
+	if bytes.Equal(outShort, outLong) {
+		t.Fatalf("single-chunk and multi-chunk messages produced the same digest")
+	}
+}
+
+func TestKangarooTwelveCustomization(t *testing.T) {
+	a := NewKangarooTwelve([]byte("A"))
+	a.Write([]byte("msg"))
+	outA := readAllK12(t, a, 32)
+
+	b := NewKangarooTwelve([]byte("B"))
+	b.Write([]byte("msg"))
+	outB := readAllK12(t, b, 32)
+
+	if bytes.Equal(outA, outB) {
+		t.Fatalf("different customization strings produced the same digest")
+	}
+}
+
+func TestKangarooTwelveClone(t *testing.T) {
+	h := NewKangarooTwelve(nil)
+	h.Write([]byte("clone me"))
+	var a, b [16]byte
+	h.Read(a[:])
+	clone := h.Clone()
+	h.Read(a[:])
+	clone.Read(b[:])
+	if !bytes.Equal(a[:], b[:]) {
+		t.Fatalf("clone diverged from original: %x != %x", a, b)
+	}
+}
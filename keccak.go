@@ -3,13 +3,15 @@
 package keccak
 
 import (
+	"errors"
 	"hash"
 )
 
 const (
-	domainNone  = 1
-	domainSHA3  = 0x06
-	domainSHAKE = 0x1f
+	domainNone   = 1
+	domainSHA3   = 0x06
+	domainSHAKE  = 0x1f
+	domainCSHAKE = 0x04
 )
 
 const rounds = 24
@@ -47,13 +49,28 @@ type keccak struct {
 	blockSize int
 	buf       []byte
 	domain    byte
+
+	// permute applies the sponge's permutation to S. It defaults to
+	// the full 24-round keccakf; callers that need a reduced-round
+	// permutation (e.g. KangarooTwelve's Keccak-p[1600,12]) may
+	// overwrite it after construction.
+	permute func(*[25]uint64)
+
+	// squeezing is true once the sponge has switched from absorbing
+	// input to squeezing output, which happens on the first Read.
+	squeezing bool
+	rateBuf   []byte
+	rateOff   int
 }
 
+var errWriteAfterRead = errors.New("keccak: write after read")
+
 func newKeccak(capacity, output int, domain byte) hash.Hash {
 	var h keccak
 	h.size = output / 8
 	h.blockSize = (200 - capacity/8)
 	h.domain = domain
+	h.permute = keccakf
 	return &h
 }
 
@@ -74,6 +91,10 @@ func New512() hash.Hash {
 }
 
 func (k *keccak) Write(b []byte) (int, error) {
+	if k.squeezing {
+		return 0, errWriteAfterRead
+	}
+
 	n := len(b)
 
 	if len(k.buf) > 0 {
@@ -92,9 +113,9 @@ func (k *keccak) Write(b []byte) (int, error) {
 		k.buf = nil
 	}
 
-	for len(b) >= k.blockSize {
-		k.absorb(b[:k.blockSize])
-		b = b[k.blockSize:]
+	if n := len(b) - len(b)%k.blockSize; n > 0 {
+		k.absorbBlocks(b[:n])
+		b = b[n:]
 	}
 
 	k.buf = b
@@ -113,6 +134,9 @@ func (k *keccak) Reset() {
 		k.S[i] = 0
 	}
 	k.buf = nil
+	k.squeezing = false
+	k.rateBuf = nil
+	k.rateOff = 0
 }
 
 func (k *keccak) Size() int {
@@ -128,10 +152,20 @@ func (k *keccak) absorb(block []byte) {
 		panic("absorb() called with invalid block size")
 	}
 
-	for i := 0; i < k.blockSize/8; i++ {
-		k.S[i] ^= uint64le(block[i*8:])
+	xorIn(&k.S, block)
+	k.permute(&k.S)
+}
+
+// absorbBlocks absorbs zero or more whole blockSize-sized blocks,
+// permuting after each one. Unlike repeated calls to absorb, the
+// permutation is applied inline so multiple blocks can be processed
+// in a single Write without re-entering per block.
+func (k *keccak) absorbBlocks(blocks []byte) {
+	for len(blocks) >= k.blockSize {
+		xorIn(&k.S, blocks[:k.blockSize])
+		k.permute(&k.S)
+		blocks = blocks[k.blockSize:]
 	}
-	keccakf(&k.S)
 }
 
 func (k *keccak) pad(block []byte) []byte {
@@ -163,137 +197,11 @@ func (k *keccak) squeeze(b []byte) []byte {
 		}
 		b = append(b, buf[:k.blockSize]...)
 		n -= k.blockSize
-		keccakf(&k.S)
+		k.permute(&k.S)
 	}
 	return b
 }
 
-func keccakf(S *[25]uint64) {
-	var bc [5]uint64
-	var tmp uint64
-
-	for r := 0; r < rounds; r++ {
-		// theta
-		bc[0] = S[0] ^ S[5] ^ S[10] ^ S[15] ^ S[20]
-		bc[1] = S[1] ^ S[6] ^ S[11] ^ S[16] ^ S[21]
-		bc[2] = S[2] ^ S[7] ^ S[12] ^ S[17] ^ S[22]
-		bc[3] = S[3] ^ S[8] ^ S[13] ^ S[18] ^ S[23]
-		bc[4] = S[4] ^ S[9] ^ S[14] ^ S[19] ^ S[24]
-		tmp = bc[4] ^ (bc[1]<<1 | bc[1]>>(64-1))
-		S[0] ^= tmp
-		S[5] ^= tmp
-		S[10] ^= tmp
-		S[15] ^= tmp
-		S[20] ^= tmp
-		tmp = bc[0] ^(bc[2]<<1 | bc[2]>>(64-1))
-		S[1] ^= tmp
-		S[6] ^= tmp
-		S[11] ^= tmp
-		S[16] ^= tmp
-		S[21] ^= tmp
-		tmp = bc[1] ^ (bc[3]<<1 | bc[3]>>(64-1))
-		S[2] ^= tmp
-		S[7] ^= tmp
-		S[12] ^= tmp
-		S[17] ^= tmp
-		S[22] ^= tmp
-		tmp = bc[2] ^  (bc[4]<<1 | bc[4]>>(64-1))
-		S[3] ^= tmp
-		S[8] ^= tmp
-		S[13] ^= tmp
-		S[18] ^= tmp
-		S[23] ^= tmp
-		tmp = bc[3] ^ (bc[0]<<1 | bc[0]>>(64-1))
-		S[4] ^= tmp
-		S[9] ^= tmp
-		S[14] ^= tmp
-		S[19] ^= tmp
-		S[24] ^= tmp
-
-		// rho phi
-		tmp = S[1]
-		tmp, S[10] = S[10], tmp << 1 | tmp >> (64- 1)
-		tmp, S[7] = S[7],   tmp << 3 | tmp >> (64- 3)
-		tmp, S[11] = S[11], tmp << 6 | tmp >> (64- 6)
-		tmp, S[17] = S[17], tmp << 10 | tmp >> (64- 10)
-		tmp, S[18] = S[18], tmp << 15 | tmp >> (64- 15)
-		tmp, S[3] = S[3],   tmp << 21 | tmp >> (64- 21)
-		tmp, S[5] = S[5],   tmp << 28 | tmp >> (64- 28)
-		tmp, S[16] = S[16], tmp << 36 | tmp >> (64- 36)
-		tmp, S[8] = S[8],   tmp << 45 | tmp >> (64- 45)
-		tmp, S[21] = S[21], tmp << 55 | tmp >> (64- 55)
-		tmp, S[24] = S[24], tmp << 2 | tmp >> (64- 2)
-		tmp, S[4] = S[4],   tmp << 14 | tmp >> (64- 14)
-		tmp, S[15] = S[15], tmp << 27 | tmp >> (64- 27)
-		tmp, S[23] = S[23], tmp << 41 | tmp >> (64- 41)
-		tmp, S[19] = S[19], tmp << 56 | tmp >> (64- 56)
-		tmp, S[13] = S[13], tmp << 8 | tmp >> (64- 8)
-		tmp, S[12] = S[12], tmp << 25 | tmp >> (64- 25)
-		tmp, S[2] = S[2],   tmp << 43 | tmp >> (64- 43)
-		tmp, S[20] = S[20], tmp << 62 | tmp >> (64- 62)
-		tmp, S[14] = S[14], tmp << 18 | tmp >> (64- 18)
-		tmp, S[22] = S[22], tmp << 39 | tmp >> (64- 39)
-		tmp, S[9] = S[9],   tmp << 61 | tmp >> (64- 61)
-		tmp, S[6] = S[6],   tmp << 20 | tmp >> (64- 20)
-		S[1] =              tmp << 44 | tmp >> (64- 44)
-
-		// chi
-		bc[0] = S[0]
-		bc[1] = S[1]
-		bc[2] = S[2]
-		bc[3] = S[3]
-		bc[4] = S[4]
-		S[0] ^= (^bc[1]) & bc[2]
-		S[1] ^= (^bc[2]) & bc[3]
-		S[2] ^= (^bc[3]) & bc[4]
-		S[3] ^= (^bc[4]) & bc[0]
-		S[4] ^= (^bc[0]) & bc[1]
-		bc[0] = S[5]
-		bc[1] = S[6]
-		bc[2] = S[7]
-		bc[3] = S[8]
-		bc[4] = S[9]
-		S[5] ^= (^bc[1]) & bc[2]
-		S[6] ^= (^bc[2]) & bc[3]
-		S[7] ^= (^bc[3]) & bc[4]
-		S[8] ^= (^bc[4]) & bc[0]
-		S[9] ^= (^bc[0]) & bc[1]
-		bc[0] = S[10]
-		bc[1] = S[11]
-		bc[2] = S[12]
-		bc[3] = S[13]
-		bc[4] = S[14]
-		S[10] ^= (^bc[1]) & bc[2]
-		S[11] ^= (^bc[2]) & bc[3]
-		S[12] ^= (^bc[3]) & bc[4]
-		S[13] ^= (^bc[4]) & bc[0]
-		S[14] ^= (^bc[0]) & bc[1]
-		bc[0] = S[15]
-		bc[1] = S[16]
-		bc[2] = S[17]
-		bc[3] = S[18]
-		bc[4] = S[19]
-		S[15] ^= (^bc[1]) & bc[2]
-		S[16] ^= (^bc[2]) & bc[3]
-		S[17] ^= (^bc[3]) & bc[4]
-		S[18] ^= (^bc[4]) & bc[0]
-		S[19] ^= (^bc[0]) & bc[1]
-		bc[0] = S[20]
-		bc[1] = S[21]
-		bc[2] = S[22]
-		bc[3] = S[23]
-		bc[4] = S[24]
-		S[20] ^= (^bc[1]) & bc[2]
-		S[21] ^= (^bc[2]) & bc[3]
-		S[22] ^= (^bc[3]) & bc[4]
-		S[23] ^= (^bc[4]) & bc[0]
-		S[24] ^= (^bc[0]) & bc[1]
-
-		// iota
-		S[0] ^= roundConstants[r]
-	}
-}
-
 func rotl64(x uint64, n uint) uint64 {
 	return (x << n) | (x >> (64 - n))
 }
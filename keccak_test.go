@@ -0,0 +1,49 @@
+package keccak
+
+import (
+	"bytes"
+	"encoding/hex"
+	"hash"
+	"testing"
+)
+
+func mustDecode(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatalf("bad hex literal %q: %v", s, err)
+	}
+	return b
+}
+
+func testSum(t *testing.T, name string, h hash.Hash, msg, want string) {
+	t.Helper()
+	h.Write([]byte(msg))
+	got := h.Sum(nil)
+	if !bytes.Equal(got, mustDecode(t, want)) {
+		t.Errorf("%s(%q) = %x, want %s", name, msg, got, want)
+	}
+}
+
+func TestKAT(t *testing.T) {
+	testSum(t, "Keccak256", New256(), "", "c5d2460186f7233c927e7db2dcc703c0e500b653ca82273b7bfad8045d85a470")
+	testSum(t, "Keccak256", New256(), "abc", "4e03657aea45a94fc7d47ba826c8d667c0d1e6e33a64a036ec44f58fa12d6c45")
+	testSum(t, "SHA3-224", NewSHA3224(), "abc", "e642824c3f8cf24ad09234ee7d3c766fc9a3a5168d0c94ad73b46fdf")
+	testSum(t, "SHA3-256", NewSHA3256(), "abc", "3a985da74fe225b2045c172d6bd390bd855f086e3e9d525b46bfe24511431532")
+	testSum(t, "SHA3-256", NewSHA3256(), "", "a7ffc6f8bf1ed76651c14756a061d662f580ff4de43b49fa82d80a4b80f8434a")
+	testSum(t, "SHA3-384", NewSHA3384(), "abc", "ec01498288516fc926459f58e2c6ad8df9b473cb0fc08c2596da7cf0e49be4b298d88cea927ac7f539f1edf228376d25")
+	testSum(t, "SHA3-512", NewSHA3512(), "abc", "b751850b1a57168a5693cd924b6b096e08f621827444f70d884f5d0240d2712e10e116e9192af3c91a7ec57647e3934057340b4cf408d5a56592f8274eec53f0")
+}
+
+func TestSize(t *testing.T) {
+	for _, h := range []hash.Hash{New224(), NewSHA3224()} {
+		if h.Size() != 28 {
+			t.Errorf("Size() = %d, want 28", h.Size())
+		}
+	}
+	for _, h := range []hash.Hash{New256(), NewSHA3256()} {
+		if h.Size() != 32 {
+			t.Errorf("Size() = %d, want 32", h.Size())
+		}
+	}
+}
@@ -0,0 +1,10 @@
+// +build amd64,!appengine,!gccgo
+
+package keccak
+
+//go:noescape
+func keccakF1600(a *[25]uint64)
+
+func keccakf(S *[25]uint64) {
+	keccakF1600(S)
+}
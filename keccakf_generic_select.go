@@ -0,0 +1,7 @@
+// +build !amd64 appengine gccgo
+
+package keccak
+
+func keccakf(S *[25]uint64) {
+	keccakfGeneric(S)
+}
@@ -1,9 +1,17 @@
 package keccak
 
 import (
+	"crypto"
 	"hash"
 )
 
+func init() {
+	crypto.RegisterHash(crypto.SHA3_224, NewSHA3224)
+	crypto.RegisterHash(crypto.SHA3_256, NewSHA3256)
+	crypto.RegisterHash(crypto.SHA3_384, NewSHA3384)
+	crypto.RegisterHash(crypto.SHA3_512, NewSHA3512)
+}
+
 func NewSHA3224() hash.Hash {
 	return newKeccak(224*2, 224, domainSHA3)
 }
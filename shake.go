@@ -2,12 +2,95 @@ package keccak
 
 import (
 	"hash"
+	"io"
 )
 
+// ShakeHash is the interface implemented by Keccak's extendable-output
+// functions (SHAKE128, SHAKE256). Unlike hash.Hash, the output is not
+// fixed at construction time: callers absorb input via Write and then
+// squeeze out as many bytes as they like via Read.
+//
+// Once Read has been called, the instance has switched from absorbing
+// to squeezing and further calls to Write return an error. Reset
+// returns it to the absorbing state.
+type ShakeHash interface {
+	io.Writer
+
+	// Read squeezes more output from the sponge. Unlike the Read
+	// method of io.Reader, it never returns an error.
+	Read(p []byte) (n int, err error)
+
+	// Clone returns a copy of the ShakeHash in its current state.
+	Clone() ShakeHash
+
+	// Reset resets the ShakeHash to its initial state.
+	Reset()
+}
+
+// NewShake128 creates a new SHAKE128 ShakeHash.
+func NewShake128() ShakeHash {
+	return newKeccak(128*2, 0, domainSHAKE).(*keccak)
+}
+
+// NewShake256 creates a new SHAKE256 ShakeHash.
+func NewShake256() ShakeHash {
+	return newKeccak(256*2, 0, domainSHAKE).(*keccak)
+}
+
+// NewSHAKE128 creates a new SHAKE128 hash.Hash that produces n bytes
+// of output from Sum. For streaming output of arbitrary length, use
+// NewShake128 instead.
 func NewSHAKE128(n int) hash.Hash {
 	return newKeccak(128*2, n*8, domainSHAKE)
 }
 
+// NewSHAKE256 creates a new SHAKE256 hash.Hash that produces n bytes
+// of output from Sum. For streaming output of arbitrary length, use
+// NewShake256 instead.
 func NewSHAKE256(n int) hash.Hash {
 	return newKeccak(256*2, n*8, domainSHAKE)
 }
+
+// Read squeezes len(p) bytes of output from the sponge into p. On the
+// first call it pads and absorbs any buffered input, switching the
+// sponge from absorbing to squeezing; subsequent Writes then fail.
+func (k *keccak) Read(p []byte) (int, error) {
+	if !k.squeezing {
+		k.absorb(k.pad(k.buf))
+		k.buf = nil
+		k.squeezing = true
+		k.rateBuf = make([]byte, k.blockSize)
+		k.fillRateBuf()
+	}
+
+	n := 0
+	for n < len(p) {
+		if k.rateOff == k.blockSize {
+			k.permute(&k.S)
+			k.fillRateBuf()
+		}
+		c := copy(p[n:], k.rateBuf[k.rateOff:])
+		n += c
+		k.rateOff += c
+	}
+	return n, nil
+}
+
+func (k *keccak) fillRateBuf() {
+	for i := 0; i < k.blockSize/8; i++ {
+		putUint64le(k.rateBuf[i*8:], k.S[i])
+	}
+	k.rateOff = 0
+}
+
+// Clone returns a copy of k in its current state.
+func (k *keccak) Clone() ShakeHash {
+	k2 := *k
+	if k.buf != nil {
+		k2.buf = append([]byte(nil), k.buf...)
+	}
+	if k.rateBuf != nil {
+		k2.rateBuf = append([]byte(nil), k.rateBuf...)
+	}
+	return &k2
+}
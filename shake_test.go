@@ -0,0 +1,70 @@
+package keccak
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestShakeMatchesFixedOutput(t *testing.T) {
+	fixed := NewSHAKE128(64)
+	fixed.Write([]byte("hello world"))
+	want := fixed.Sum(nil)
+
+	xof := NewShake128()
+	xof.Write([]byte("hello world"))
+	got := make([]byte, 64)
+	if _, err := xof.Read(got); err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if !bytes.Equal(want, got) {
+		t.Fatalf("NewShake128 streamed = %x, want %x", got, want)
+	}
+}
+
+func TestShakeReadInChunks(t *testing.T) {
+	want := make([]byte, 256)
+	h := NewShake128()
+	h.Write([]byte("chunked"))
+	h.Read(want)
+
+	got := make([]byte, 256)
+	h2 := NewShake128()
+	h2.Write([]byte("chunked"))
+	for off := 0; off < len(got); {
+		end := off + 7
+		if end > len(got) {
+			end = len(got)
+		}
+		n, err := h2.Read(got[off:end])
+		if err != nil {
+			t.Fatalf("Read: %v", err)
+		}
+		off += n
+	}
+	if !bytes.Equal(want, got) {
+		t.Fatalf("chunked read diverged from single read")
+	}
+}
+
+func TestShakeWriteAfterReadErrors(t *testing.T) {
+	h := NewShake128()
+	h.Write([]byte("x"))
+	var tmp [8]byte
+	h.Read(tmp[:])
+	if _, err := h.Write([]byte("y")); err == nil {
+		t.Fatalf("Write after Read succeeded, want error")
+	}
+}
+
+func TestShakeClone(t *testing.T) {
+	h := NewShake128()
+	h.Write([]byte("clone me"))
+	var a, b [16]byte
+	h.Read(a[:])
+	clone := h.Clone()
+	h.Read(a[:])
+	clone.Read(b[:])
+	if !bytes.Equal(a[:], b[:]) {
+		t.Fatalf("clone diverged from original: %x != %x", a, b)
+	}
+}
@@ -0,0 +1,12 @@
+// +build !amd64,!arm64,!ppc64le appengine
+
+package keccak
+
+// xorIn XORs block into the first len(block)/8 lanes of S, assembling
+// each little-endian lane byte by byte. This is the portable fallback
+// for architectures without cheap unaligned 64-bit loads.
+func xorIn(S *[25]uint64, block []byte) {
+	for i := 0; i < len(block)/8; i++ {
+		S[i] ^= uint64le(block[i*8:])
+	}
+}
@@ -0,0 +1,19 @@
+// +build amd64 arm64 ppc64le
+// +build !appengine
+
+package keccak
+
+import "unsafe"
+
+// xorIn XORs block into the first len(block)/8 lanes of S. block's
+// length is always a multiple of 8 (every Keccak rate is), so on
+// these little-endian architectures, which tolerate unaligned loads,
+// it can be reinterpreted as a []uint64 and XORed in directly instead
+// of assembling each lane byte by byte. s390x is excluded despite
+// having cheap unaligned access because it is big-endian.
+func xorIn(S *[25]uint64, block []byte) {
+	src := (*[21]uint64)(unsafe.Pointer(&block[0]))
+	for i := 0; i < len(block)/8; i++ {
+		S[i] ^= src[i]
+	}
+}